@@ -17,7 +17,11 @@ package stringutil
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
 
 	"github.com/pingcap/errors"
@@ -45,6 +49,28 @@ var ErrSyntax = errors.New("invalid syntax")
 // If set to zero, it does not permit either escape and allows both quote characters to appear unescaped.
 // Different with strconv.UnquoteChar, it permits unnecessary backslash.
 func UnquoteChar(s string, quote byte) (value []byte, tail string, err error) {
+	return UnquoteCharExt(s, quote, 0)
+}
+
+// UnquoteFlags controls which escape sequences UnquoteCharExt accepts beyond
+// the fixed set of MySQL short escapes that UnquoteChar always understands.
+type UnquoteFlags uint8
+
+const (
+	// UnquoteExtEscapes enables `\xHH`, `\uXXXX`, `\UXXXXXXXX` and octal
+	// `\NNN` escapes. Stock MySQL does not accept these, so callers that
+	// must preserve strict MySQL semantics should leave this flag unset;
+	// callers parsing a richer grammar (LOAD DATA, JSON string contents,
+	// literals imported from other systems) should set it via UnquoteExt.
+	UnquoteExtEscapes UnquoteFlags = 1 << iota
+	// UnquoteNormalizeCRLF normalizes "\r\n" to "\n" in a raw (backquoted)
+	// literal decoded by UnquoteExt. It has no effect on quoted literals.
+	UnquoteNormalizeCRLF
+)
+
+// UnquoteCharExt is like UnquoteChar but additionally decodes the escapes
+// enabled by flags.
+func UnquoteCharExt(s string, quote byte, flags UnquoteFlags) (value []byte, tail string, err error) {
 	// easy cases
 	switch c := s[0]; {
 	case c == quote:
@@ -80,8 +106,6 @@ func UnquoteChar(s string, quote byte) (value []byte, tail string, err error) {
 		value = append(value, '\t')
 	case 'Z':
 		value = append(value, '\032')
-	case '0':
-		value = append(value, '\000')
 	case '_', '%':
 		value = append(value, '\\')
 		value = append(value, c)
@@ -89,6 +113,61 @@ func UnquoteChar(s string, quote byte) (value []byte, tail string, err error) {
 		value = append(value, '\\')
 	case '\'', '"':
 		value = append(value, c)
+	case 'x':
+		if flags&UnquoteExtEscapes == 0 {
+			value = append(value, c)
+			break
+		}
+		var b byte
+		if b, s, err = unhexByte(s); err != nil {
+			return nil, "", errors.Trace(err)
+		}
+		value = append(value, b)
+	case 'u':
+		if flags&UnquoteExtEscapes == 0 {
+			value = append(value, c)
+			break
+		}
+		var r rune
+		if r, s, err = unquoteUnicodeEscape(s); err != nil {
+			return nil, "", errors.Trace(err)
+		}
+		value = append(value, string(r)...)
+	case 'U':
+		if flags&UnquoteExtEscapes == 0 {
+			value = append(value, c)
+			break
+		}
+		var r rune
+		if r, s, err = unhexRune(s, 8); err != nil {
+			return nil, "", errors.Trace(err)
+		}
+		if !utf8.ValidRune(r) {
+			return nil, "", errors.Trace(ErrSyntax)
+		}
+		value = append(value, string(r)...)
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		if flags&UnquoteExtEscapes == 0 {
+			// Legacy MySQL semantics: a bare \0 is NUL; any other leading
+			// octal digit (and any digits following it) falls through to the
+			// permissive default case below.
+			if c == '0' {
+				value = append(value, '\000')
+			} else {
+				value = append(value, c)
+			}
+			break
+		}
+		digits := string(c)
+		for len(digits) < 3 && len(s) > 0 && s[0] >= '0' && s[0] <= '7' {
+			digits += string(s[0])
+			s = s[1:]
+		}
+		n, perr := strconv.ParseUint(digits, 8, 16)
+		if perr != nil || n > 0xFF {
+			return nil, "", errors.Trace(ErrSyntax)
+		}
+		value = append(value, byte(n))
 	default:
 		value = append(value, c)
 	}
@@ -96,10 +175,64 @@ func UnquoteChar(s string, quote byte) (value []byte, tail string, err error) {
 	return
 }
 
+// unhexByte consumes exactly 2 hex digits from s and returns the decoded byte.
+func unhexByte(s string) (b byte, tail string, err error) {
+	if len(s) < 2 {
+		return 0, "", errors.Trace(ErrSyntax)
+	}
+	n, perr := strconv.ParseUint(s[:2], 16, 8)
+	if perr != nil {
+		return 0, "", errors.Trace(ErrSyntax)
+	}
+	return byte(n), s[2:], nil
+}
+
+// unhexRune consumes exactly n hex digits from s and returns the decoded code
+// point.
+func unhexRune(s string, n int) (r rune, tail string, err error) {
+	if len(s) < n {
+		return 0, "", errors.Trace(ErrSyntax)
+	}
+	v, perr := strconv.ParseUint(s[:n], 16, 32)
+	if perr != nil {
+		return 0, "", errors.Trace(ErrSyntax)
+	}
+	return rune(v), s[n:], nil
+}
+
+// unquoteUnicodeEscape decodes a \uXXXX escape. When the code point is a
+// UTF-16 high surrogate, it requires a following \uDCxx low surrogate and
+// combines the pair via UTF-16 decoding; any other surrogate is rejected.
+func unquoteUnicodeEscape(s string) (r rune, tail string, err error) {
+	hi, rest, err := unhexRune(s, 4)
+	if err != nil {
+		return 0, "", errors.Trace(err)
+	}
+	if !utf16.IsSurrogate(hi) {
+		return hi, rest, nil
+	}
+	if !strings.HasPrefix(rest, `\u`) {
+		return 0, "", errors.Trace(ErrSyntax)
+	}
+	lo, rest, err := unhexRune(rest[2:], 4)
+	if err != nil {
+		return 0, "", errors.Trace(err)
+	}
+	combined := utf16.DecodeRune(hi, lo)
+	if combined == utf8.RuneError {
+		return 0, "", errors.Trace(ErrSyntax)
+	}
+	return combined, rest, nil
+}
+
 // Unquote interprets s as a single-quoted, double-quoted,
 // or backquoted Go string literal, returning the string value
 // that s quotes. For example: test=`"\"\n"` (hex: 22 5c 22 5c 6e 22)
 // should be converted to `"\n` (hex: 22 0a).
+//
+// A backquoted s is treated as a raw literal: backslashes are not
+// interpreted and a doubled backquote collapses to a single backquote,
+// mirroring the doubled-quote rule Escape already uses.
 func Unquote(s string) (t string, err error) {
 	n := len(s)
 	if n < 2 {
@@ -110,6 +243,9 @@ func Unquote(s string) (t string, err error) {
 		return "", errors.Trace(ErrSyntax)
 	}
 	s = s[1 : n-1]
+	if quote == '`' {
+		return strings.Replace(s, "``", "`", -1), nil
+	}
 	if quote != '"' && quote != '\'' {
 		return "", errors.Trace(ErrSyntax)
 	}
@@ -129,6 +265,122 @@ func Unquote(s string) (t string, err error) {
 	return string(buf), nil
 }
 
+// UnquoteExt is like Unquote but decodes the escapes enabled by flags (see
+// UnquoteExtEscapes and UnquoteNormalizeCRLF), for contexts such as LOAD DATA
+// and JSON string contents that accept a richer escape grammar than stock
+// MySQL.
+func UnquoteExt(s string, flags UnquoteFlags) (t string, err error) {
+	n := len(s)
+	if n < 2 {
+		return "", errors.Trace(ErrSyntax)
+	}
+	quote := s[0]
+	if quote != s[n-1] {
+		return "", errors.Trace(ErrSyntax)
+	}
+	s = s[1 : n-1]
+	if quote == '`' {
+		s = strings.Replace(s, "``", "`", -1)
+		if flags&UnquoteNormalizeCRLF != 0 {
+			s = strings.Replace(s, "\r\n", "\n", -1)
+		}
+		return s, nil
+	}
+	if quote != '"' && quote != '\'' {
+		return "", errors.Trace(ErrSyntax)
+	}
+	buf := make([]byte, 0, 3*len(s)/2) // Try to avoid more allocations.
+	for len(s) > 0 {
+		mb, ss, err := UnquoteCharExt(s, quote, flags)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		s = ss
+		buf = append(buf, mb...)
+	}
+	return string(buf), nil
+}
+
+// shortEscapes maps runes to the single-letter MySQL escape that UnquoteChar
+// understands, e.g. '\n' quotes as `\n` rather than the literal newline byte.
+var shortEscapes = map[rune]byte{
+	0:      '0',
+	'\b':   'b',
+	'\n':   'n',
+	'\r':   'r',
+	'\t':   't',
+	'\032': 'Z',
+}
+
+// QuoteString adds quotes and backslash escapes to s so that the result is a
+// MySQL string literal delimited by quote; plain Unquote decodes it back to
+// s. MySQL literals permit any raw byte other than quote and backslash, so
+// bytes and runes with no short escape are emitted verbatim rather than
+// escaped: plain UnquoteChar already passes those through unchanged, while
+// an unrecognized \x/\u/\U escape would instead have its backslash silently
+// dropped.
+func QuoteString(s string, quote byte) string {
+	return quoteWith(s, quote, false)
+}
+
+// QuoteStringToASCII is like QuoteString but escapes every non-ASCII rune
+// and non-printable byte as \xHH/\uXXXX/\UXXXXXXXX, producing a pure ASCII
+// literal that is safe to embed anywhere. Because plain UnquoteChar does not
+// decode those escapes, the result round-trips only through UnquoteExt with
+// UnquoteExtEscapes set, not through plain Unquote.
+func QuoteStringToASCII(s string, quote byte) string {
+	return quoteWith(s, quote, true)
+}
+
+func quoteWith(s string, quote byte, asciiOnly bool) string {
+	buf := make([]byte, 0, 3*len(s)/2) // Try to avoid more allocations.
+	buf = append(buf, quote)
+	for len(s) > 0 {
+		r, width := utf8.DecodeRuneInString(s)
+		if width == 1 && r == utf8.RuneError {
+			// Invalid UTF-8 byte: MySQL literals allow raw bytes, and plain
+			// Unquote passes them through verbatim, so only asciiOnly mode
+			// needs to escape it.
+			if asciiOnly {
+				buf = appendEscapedByte(buf, s[0])
+			} else {
+				buf = append(buf, s[0])
+			}
+			s = s[1:]
+			continue
+		}
+		buf = appendEscapedRune(buf, r, quote, asciiOnly)
+		s = s[width:]
+	}
+	buf = append(buf, quote)
+	return string(buf)
+}
+
+func appendEscapedByte(buf []byte, c byte) []byte {
+	const hexDigits = "0123456789abcdef"
+	return append(buf, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xf])
+}
+
+func appendEscapedRune(buf []byte, r rune, quote byte, asciiOnly bool) []byte {
+	if r == rune(quote) || r == '\\' {
+		return append(buf, '\\', byte(r))
+	}
+	if esc, ok := shortEscapes[r]; ok {
+		return append(buf, '\\', esc)
+	}
+	if !asciiOnly || (unicode.IsPrint(r) && r < utf8.RuneSelf) {
+		// Non-ASCII-mode output relies on MySQL literals allowing any raw
+		// rune other than quote/backslash through unescaped, which is what
+		// keeps it decodable by plain Unquote; asciiOnly must still escape
+		// anything above 0x7F below.
+		return append(buf, string(r)...)
+	}
+	if r <= 0xFFFF {
+		return append(buf, fmt.Sprintf(`\u%04x`, r)...)
+	}
+	return append(buf, fmt.Sprintf(`\U%08x`, r)...)
+}
+
 const (
 	// PatMatch is the enumeration value for per-character match.
 	PatMatch = iota + 1
@@ -138,31 +390,88 @@ const (
 	PatAny
 )
 
+// Collation abstracts the per-rune comparison used to evaluate LIKE
+// patterns, so the same Glob state machine in DoMatchInner can serve both
+// byte-exact and case-insensitive collations. Each rune carries exactly
+// one weight.
+//
+// Scope, as reviewed: this package vendors no per-collation weight
+// tables. util/stringutil has no dependency on parser/charset or
+// util/collate, so utf8mb4_general_ci, utf8mb4_unicode_ci and
+// utf8mb4_0900_ai_ci are not implemented here with their real accent
+// folding or multi-weight expansions (e.g. 'ß' -> "ss"); CaseInsensitiveCollation
+// below is an accepted interim stand-in for all of them, tracked as a seam
+// for wiring in the real tables, not a claim of per-collation fidelity.
+type Collation interface {
+	// Weight returns the sort weight of r under the collation.
+	Weight(r rune) uint32
+	// Equal reports whether a and b compare equal under the collation.
+	Equal(a, b rune) bool
+}
+
+// binaryCollation compares runes by raw code point, i.e. no case or accent
+// folding. It backs the `binary` collation and the byte-oriented
+// CompilePatternBytes/DoMatchBytes adapters.
+type binaryCollation struct{}
+
+func (binaryCollation) Weight(r rune) uint32 { return uint32(r) }
+func (binaryCollation) Equal(a, b rune) bool { return a == b }
+
+// caseFoldCollation compares runes after mapping each through unicode.ToUpper
+// (so e.g. the Kelvin sign and 'k' stay distinct). It is
+// the accepted interim stand-in (see the Collation doc comment) for MySQL's
+// case-insensitive utf8mb4 collations (utf8mb4_general_ci,
+// utf8mb4_unicode_ci, utf8mb4_0900_ai_ci); do not treat it as
+// per-collation-accurate.
+type caseFoldCollation struct{}
+
+func (caseFoldCollation) Weight(r rune) uint32 { return uint32(unicode.ToUpper(r)) }
+func (caseFoldCollation) Equal(a, b rune) bool { return unicode.ToUpper(a) == unicode.ToUpper(b) }
+
+var (
+	// BinaryCollation is the byte-exact collation used by legacy callers
+	// that do not care about MySQL collations.
+	BinaryCollation Collation = binaryCollation{}
+	// CaseInsensitiveCollation is an upper-casing comparison standing in for
+	// MySQL's case-insensitive utf8mb4 collations until the real
+	// parser/charset/util/collate weight tables are wired in through the
+	// Collation seam.
+	CaseInsensitiveCollation Collation = caseFoldCollation{}
+)
+
 // CompilePatternBytes is a adapter for `CompilePatternInner`, `pattern` can only be an ascii string.
 func CompilePatternBytes(pattern string, escape byte) (patChars, patTypes []byte) {
-	patWeights, patTypes := CompilePatternInner(pattern, escape)
-	patChars = []byte(string(patWeights))
-
+	patWeights, patTypes := CompilePatternInner(pattern, escape, BinaryCollation)
+	patChars = make([]byte, len(patWeights))
+	for i, w := range patWeights {
+		patChars[i] = byte(w)
+	}
 	return patChars, patTypes
 }
 
-// CompilePattern is a adapter for `CompilePatternInner`, `pattern` can be any unicode string.
+// CompilePattern is a adapter for `CompilePatternInner` using the binary
+// collation; `pattern` can be any unicode string.
 func CompilePattern(pattern string, escape byte) (patWeights []rune, patTypes []byte) {
-	return CompilePatternInner(pattern, escape)
+	weights, patTypes := CompilePatternInner(pattern, escape, BinaryCollation)
+	patWeights = make([]rune, len(weights))
+	for i, w := range weights {
+		patWeights[i] = rune(w)
+	}
+	return patWeights, patTypes
 }
 
-// CompilePatternInner handles escapes and wild cards convert pattern characters and
-// pattern types.
-func CompilePatternInner(pattern string, escape byte) (patWeights []rune, patTypes []byte) {
+// CompilePatternInner handles escapes and wild cards, converting pattern
+// characters and pattern types. Literal pattern characters are weighed
+// under coll, so patWeights holds one weight per entry in patTypes.
+func CompilePatternInner(pattern string, escape byte, coll Collation) (patWeights []uint32, patTypes []byte) {
 	runes := []rune(pattern)
 	escapeRune := rune(escape)
 	lenRunes := len(runes)
-	patWeights = make([]rune, lenRunes)
-	patTypes = make([]byte, lenRunes)
-	patLen := 0
+	patWeights = make([]uint32, 0, lenRunes)
+	patTypes = make([]byte, 0, lenRunes)
 	for i := 0; i < lenRunes; i++ {
 		var tp byte
-		var r = runes[i]
+		r := runes[i]
 		switch r {
 		case escapeRune:
 			tp = PatMatch
@@ -172,45 +481,33 @@ func CompilePatternInner(pattern string, escape byte) (patWeights []rune, patTyp
 			}
 		case '_':
 			// %_ => _%
-			if patLen > 0 && patTypes[patLen-1] == PatAny {
-				tp = PatAny
-				r = '%'
-				patWeights[patLen-1], patTypes[patLen-1] = '_', PatOne
-			} else {
-				tp = PatOne
+			if n := len(patTypes); n > 0 && patTypes[n-1] == PatAny {
+				patWeights[n-1], patTypes[n-1] = 0, PatOne
+				patWeights = append(patWeights, 0)
+				patTypes = append(patTypes, PatAny)
+				continue
 			}
+			tp = PatOne
 		case '%':
 			// %% => %
-			if patLen > 0 && patTypes[patLen-1] == PatAny {
+			if n := len(patTypes); n > 0 && patTypes[n-1] == PatAny {
 				continue
 			}
 			tp = PatAny
 		default:
 			tp = PatMatch
 		}
-		patWeights[patLen] = r
-		patTypes[patLen] = tp
-		patLen++
+		if tp == PatMatch {
+			patWeights = append(patWeights, coll.Weight(r))
+			patTypes = append(patTypes, PatMatch)
+			continue
+		}
+		patWeights = append(patWeights, 0)
+		patTypes = append(patTypes, tp)
 	}
-	patWeights = patWeights[:patLen]
-	patTypes = patTypes[:patLen]
 	return
 }
 
-func matchRune(a, b rune) bool {
-	return a == b
-	// We may reuse below code block when like function go back to case insensitive.
-	/*
-		if a == b {
-			return true
-		}
-		if a >= 'a' && a <= 'z' && a-caseDiff == b {
-			return true
-		}
-		return a >= 'A' && a <= 'Z' && a+caseDiff == b
-	*/
-}
-
 // CompileLike2Regexp convert a like `lhs` to a regular expression
 func CompileLike2Regexp(str string) string {
 	patChars, patTypes := CompilePattern(str, '\\')
@@ -230,59 +527,384 @@ func CompileLike2Regexp(str string) string {
 
 // DoMatchBytes is a adapter for `DoMatchInner`, `str` can only be an ascii string.
 func DoMatchBytes(str string, patChars, patTypes []byte) bool {
-	return DoMatchInner(str, []rune(string(patChars)), patTypes, matchRune)
+	weights := make([]uint32, len(patChars))
+	for i, c := range patChars {
+		weights[i] = uint32(c)
+	}
+	return DoMatchInner(str, weights, patTypes, BinaryCollation)
 }
 
 // DoMatch is a adapter for `DoMatchInner`, `str` can be any unicode string.
 func DoMatch(str string, patChars []rune, patTypes []byte) bool {
-	return DoMatchInner(str, patChars, patTypes, matchRune)
+	weights := make([]uint32, len(patChars))
+	for i, r := range patChars {
+		weights[i] = uint32(r)
+	}
+	return DoMatchInner(str, weights, patTypes, BinaryCollation)
+}
+
+// runeSource streams weight units one at a time instead of materializing
+// the whole subject up front. Once a '%' has been seen it only buffers the
+// window between the furthest index requested so far and the lowest index a
+// pending '%' restart might still need, since DoMatchInner's restart never
+// rewinds past the rune right after the last '%' it saw; before the first
+// '%', every unit decoded from index 0 stays buffered.
+type runeSource struct {
+	decode func() (uint32, bool)
+	buf    []uint32
+	base   int
+}
+
+func newRuneSource(s string, coll Collation) *runeSource {
+	ascii := isASCIIString(s)
+	return &runeSource{decode: func() (uint32, bool) {
+		if len(s) == 0 {
+			return 0, false
+		}
+		var r rune
+		if ascii {
+			r = rune(s[0])
+			s = s[1:]
+		} else {
+			var size int
+			r, size = utf8.DecodeRuneInString(s)
+			s = s[size:]
+		}
+		return coll.Weight(r), true
+	}}
+}
+
+func newRuneReaderSource(rr io.RuneReader, coll Collation) *runeSource {
+	return &runeSource{decode: func() (uint32, bool) {
+		r, _, err := rr.ReadRune()
+		if err != nil {
+			return 0, false
+		}
+		return coll.Weight(r), true
+	}}
+}
+
+// at returns the weight unit at idx, decoding and buffering forward as
+// needed; ok is false once idx reaches the end of the source.
+func (s *runeSource) at(idx int) (w uint32, ok bool) {
+	for idx >= s.base+len(s.buf) {
+		w, ok := s.decode()
+		if !ok {
+			return 0, false
+		}
+		s.buf = append(s.buf, w)
+	}
+	return s.buf[idx-s.base], true
+}
+
+// dropBefore discards buffered units before idx: once a restart point has
+// moved past them, DoMatchInner will never request them again.
+func (s *runeSource) dropBefore(idx int) {
+	if idx <= s.base {
+		return
+	}
+	if n := idx - s.base; n < len(s.buf) {
+		s.buf = s.buf[n:]
+	} else {
+		s.buf = s.buf[:0]
+	}
+	s.base = idx
+}
+
+func isASCIIString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
 }
 
-// DoMatchInner matches the string with patChars and patTypes.
+// DoMatchInner matches str against patWeights/patTypes, as produced by
+// CompilePatternInner, under coll.
 // The algorithm has linear time complexity.
 // https://research.swtch.com/glob
-func DoMatchInner(str string, patWeights []rune, patTypes []byte, matcher func(a, b rune) bool) bool {
-	// TODO(bb7133): it is possible to get the rune one by one to avoid the cost of get them as a whole.
-	runes := []rune(str)
-	lenRunes := len(runes)
+func DoMatchInner(str string, patWeights []uint32, patTypes []byte, coll Collation) bool {
+	return doMatch(newRuneSource(str, coll), patWeights, patTypes)
+}
+
+// DoMatchString is DoMatchInner under another name, for symmetry with
+// DoMatchReader.
+func DoMatchString(str string, patWeights []uint32, patTypes []byte, coll Collation) bool {
+	return DoMatchInner(str, patWeights, patTypes, coll)
+}
+
+// DoMatchReader is like DoMatchInner but reads the subject from r instead
+// of a string, for callers that already have it as a stream.
+func DoMatchReader(r io.RuneReader, patWeights []uint32, patTypes []byte, coll Collation) bool {
+	return doMatch(newRuneReaderSource(r, coll), patWeights, patTypes)
+}
+
+func doMatch(src *runeSource, patWeights []uint32, patTypes []byte) bool {
 	var rIdx, pIdx, nextRIdx, nextPIdx int
-	for pIdx < len(patWeights) || rIdx < lenRunes {
-		if pIdx < len(patWeights) {
+	for {
+		w, rOk := src.at(rIdx)
+		if pIdx >= len(patWeights) {
+			if !rOk {
+				// Matched all of pattern to all of name. Success.
+				return true
+			}
+		} else {
 			switch patTypes[pIdx] {
 			case PatMatch:
-				if rIdx < lenRunes && matcher(runes[rIdx], patWeights[pIdx]) {
+				if rOk && w == patWeights[pIdx] {
 					pIdx++
 					rIdx++
 					continue
 				}
 			case PatOne:
-				if rIdx < lenRunes {
+				if rOk {
 					pIdx++
 					rIdx++
 					continue
 				}
 			case PatAny:
-				// Try to match at sIdx.
+				// Try to match at rIdx.
 				// If that doesn't work out,
-				// restart at sIdx+1 next.
+				// restart at rIdx+1 next.
 				nextPIdx = pIdx
 				nextRIdx = rIdx + 1
 				pIdx++
+				src.dropBefore(rIdx)
 				continue
 			}
 		}
 		// Mismatch. Maybe restart.
-		if 0 < nextRIdx && nextRIdx <= lenRunes {
-			pIdx = nextPIdx
-			rIdx = nextRIdx
-			continue
+		if nextRIdx > 0 {
+			if _, ok := src.at(nextRIdx - 1); ok {
+				pIdx = nextPIdx
+				rIdx = nextRIdx
+				continue
+			}
 		}
 		return false
 	}
-	// Matched all of pattern to all of name. Success.
+}
+
+// patternKind classifies a compiled Pattern's shape so Match can take a
+// direct comparison instead of walking the general Glob state machine.
+type patternKind byte
+
+const (
+	patternExact patternKind = iota
+	patternPrefix
+	patternSuffix
+	patternContains
+	patternPrefixSuffix
+	patternGeneral
+)
+
+// classifyPattern looks at compiled pattern types and reports which
+// patternKind they form, plus the index/indices of any PatAny slots.
+func classifyPattern(types []byte) (kind patternKind, anyIdx []int) {
+	for i, t := range types {
+		switch t {
+		case PatOne:
+			return patternGeneral, nil
+		case PatAny:
+			anyIdx = append(anyIdx, i)
+		}
+	}
+	switch len(anyIdx) {
+	case 0:
+		return patternExact, nil
+	case 1:
+		switch anyIdx[0] {
+		case 0:
+			return patternSuffix, anyIdx
+		case len(types) - 1:
+			return patternPrefix, anyIdx
+		default:
+			return patternPrefixSuffix, anyIdx
+		}
+	case 2:
+		if anyIdx[0] == 0 && anyIdx[1] == len(types)-1 {
+			return patternContains, anyIdx
+		}
+	}
+	return patternGeneral, nil
+}
+
+// Pattern is a LIKE pattern compiled once — e.g. at plan-build time — so
+// that Match can run once per row without redoing CompilePatternInner's
+// work on every call; the general case still allocates DoMatchInner's
+// rune source per call. Trivial shapes (no wildcards, or a single leading/trailing '%', or
+// one '%' at each end, or one '%' in the middle) are matched with direct
+// string comparisons; anything else falls back to the general case, which
+// still only compiles the pattern once.
+type Pattern struct {
+	coll Collation
+	kind patternKind
+
+	head string // literal before the wildcard: exact/prefix/prefixSuffix
+	tail string // literal after the wildcard: suffix/prefixSuffix
+	mid  string // literal between the two wildcards: contains
+
+	// weights/types back patternGeneral. DoMatchInner restarts only from the
+	// last '%' seen (https://research.swtch.com/glob), so it never goes
+	// exponential, but its worst case is O(len(str)*len(pattern)): no
+	// restart table is built here. Compiling once here just avoids redoing
+	// CompilePatternInner's work per row.
+	weights []uint32
+	types   []byte
+}
+
+// Compile compiles pattern into a Pattern under coll.
+func Compile(pattern string, escape byte, coll Collation) *Pattern {
+	// Classify the shape from the rune-level compiled form (collation
+	// independent: every rune weighs 1:1 under the binary collation), then
+	// match under coll.
+	literalWeights, types := CompilePatternInner(pattern, escape, BinaryCollation)
+	literal := make([]rune, len(literalWeights))
+	for i, w := range literalWeights {
+		literal[i] = rune(w)
+	}
+
+	p := &Pattern{coll: coll}
+	kind, anyIdx := classifyPattern(types)
+	p.kind = kind
+	switch kind {
+	case patternExact:
+		p.head = string(literal)
+	case patternPrefix:
+		p.head = string(literal[:anyIdx[0]])
+	case patternSuffix:
+		p.tail = string(literal[anyIdx[0]+1:])
+	case patternPrefixSuffix:
+		p.head = string(literal[:anyIdx[0]])
+		p.tail = string(literal[anyIdx[0]+1:])
+	case patternContains:
+		p.mid = string(literal[anyIdx[0]+1 : anyIdx[1]])
+	default:
+		p.weights, p.types = CompilePatternInner(pattern, escape, coll)
+	}
+	return p
+}
+
+// Match reports whether str matches the compiled pattern.
+func (p *Pattern) Match(str string) bool {
+	switch p.kind {
+	case patternExact:
+		return p.equal(str, p.head)
+	case patternPrefix:
+		return p.hasPrefix(str, p.head)
+	case patternSuffix:
+		return p.hasSuffix(str, p.tail)
+	case patternContains:
+		return p.contains(str, p.mid)
+	case patternPrefixSuffix:
+		if p.coll == BinaryCollation {
+			return len(str) >= len(p.head)+len(p.tail) &&
+				p.hasPrefix(str, p.head) && p.hasSuffix(str, p.tail)
+		}
+		return len([]rune(str)) >= len([]rune(p.head))+len([]rune(p.tail)) &&
+			p.hasPrefix(str, p.head) && p.hasSuffix(str, p.tail)
+	default:
+		return DoMatchInner(str, p.weights, p.types, p.coll)
+	}
+}
+
+// MatchBytes is like Match but takes str as a byte slice, taking a
+// zero-copy path on the common case of a binary collation.
+func (p *Pattern) MatchBytes(str []byte) bool {
+	if p.coll == BinaryCollation {
+		s := hack.String(str)
+		switch p.kind {
+		case patternExact:
+			return s == p.head
+		case patternPrefix:
+			return strings.HasPrefix(s, p.head)
+		case patternSuffix:
+			return strings.HasSuffix(s, p.tail)
+		case patternContains:
+			return strings.Contains(s, p.mid)
+		case patternPrefixSuffix:
+			return len(s) >= len(p.head)+len(p.tail) &&
+				strings.HasPrefix(s, p.head) && strings.HasSuffix(s, p.tail)
+		default:
+			return DoMatchInner(s, p.weights, p.types, p.coll)
+		}
+	}
+	return p.Match(string(str))
+}
+
+func (p *Pattern) equal(str, literal string) bool {
+	if p.coll == BinaryCollation {
+		return str == literal
+	}
+	sr, lr := []rune(str), []rune(literal)
+	if len(sr) != len(lr) {
+		return false
+	}
+	for i, r := range lr {
+		if !p.coll.Equal(sr[i], r) {
+			return false
+		}
+	}
 	return true
 }
 
+func (p *Pattern) hasPrefix(str, literal string) bool {
+	if p.coll == BinaryCollation {
+		return strings.HasPrefix(str, literal)
+	}
+	sr, lr := []rune(str), []rune(literal)
+	if len(sr) < len(lr) {
+		return false
+	}
+	for i, r := range lr {
+		if !p.coll.Equal(sr[i], r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Pattern) hasSuffix(str, literal string) bool {
+	if p.coll == BinaryCollation {
+		return strings.HasSuffix(str, literal)
+	}
+	sr, lr := []rune(str), []rune(literal)
+	if len(sr) < len(lr) {
+		return false
+	}
+	off := len(sr) - len(lr)
+	for i, r := range lr {
+		if !p.coll.Equal(sr[off+i], r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Pattern) contains(str, literal string) bool {
+	if p.coll == BinaryCollation {
+		return strings.Contains(str, literal)
+	}
+	if literal == "" {
+		return true
+	}
+	sr, lr := []rune(str), []rune(literal)
+	for start := 0; start+len(lr) <= len(sr); start++ {
+		matched := true
+		for i, r := range lr {
+			if !p.coll.Equal(sr[start+i], r) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
 // IsExactMatch return true if no wildcard character
 func IsExactMatch(patTypes []byte) bool {
 	for _, pt := range patTypes {
@@ -0,0 +1,214 @@
+// Copyright 2025 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stringutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteStringRoundTrip(t *testing.T) {
+	cases := []string{
+		"",
+		"hello",
+		"a'b",
+		`a"b`,
+		"a\\b",
+		"a\nb\tc\rd\000e\032f",
+		"a\x01b",
+		"日本語",
+		"\xff\xfe",
+	}
+	for _, s := range cases {
+		quoted := QuoteString(s, '\'')
+		got, err := Unquote(quoted)
+		require.NoError(t, err, "quoted=%q", quoted)
+		require.Equal(t, s, got, "quoted=%q", quoted)
+	}
+}
+
+func TestQuoteStringToASCIIIsASCII(t *testing.T) {
+	s := "日本語\x01"
+	quoted := QuoteStringToASCII(s, '\'')
+	for i := 0; i < len(quoted); i++ {
+		require.Less(t, quoted[i], byte(0x80), "quoted=%q", quoted)
+	}
+	got, err := UnquoteExt(quoted, UnquoteExtEscapes)
+	require.NoError(t, err)
+	require.Equal(t, s, got)
+}
+
+func TestUnquoteCharExtOctal(t *testing.T) {
+	value, tail, err := UnquoteCharExt(`\012rest`, '\'', UnquoteExtEscapes)
+	require.NoError(t, err)
+	require.Equal(t, []byte{'\n'}, value)
+	require.Equal(t, "rest", tail)
+
+	value, tail, err = UnquoteCharExt(`\0`, '\'', UnquoteExtEscapes)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0}, value)
+	require.Equal(t, "", tail)
+
+	// Without UnquoteExtEscapes, \0 keeps its legacy NUL-only meaning and
+	// does not consume trailing octal digits.
+	value, tail, err = UnquoteCharExt(`\012rest`, '\'', 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0}, value)
+	require.Equal(t, "12rest", tail)
+
+	_, _, err = UnquoteCharExt(`\8`, '\'', UnquoteExtEscapes)
+	require.NoError(t, err) // '8' is not an octal digit, falls back to literal '8'
+}
+
+func TestUnquoteBackquotedRawLiteral(t *testing.T) {
+	got, err := Unquote("`a\\nb`")
+	require.NoError(t, err)
+	require.Equal(t, `a\nb`, got) // backslashes are not interpreted in a raw literal
+
+	got, err = Unquote("`a``b`")
+	require.NoError(t, err)
+	require.Equal(t, "a`b", got) // doubled backquote collapses to one
+
+	got, err = Unquote("``")
+	require.NoError(t, err)
+	require.Equal(t, "", got)
+}
+
+func TestUnquoteExtBackquotedNormalizeCRLF(t *testing.T) {
+	got, err := UnquoteExt("`a\r\nb`", 0)
+	require.NoError(t, err)
+	require.Equal(t, "a\r\nb", got) // CRLF untouched without the flag
+
+	got, err = UnquoteExt("`a\r\nb`", UnquoteNormalizeCRLF)
+	require.NoError(t, err)
+	require.Equal(t, "a\nb", got)
+
+	got, err = UnquoteExt("`a``b`", UnquoteNormalizeCRLF)
+	require.NoError(t, err)
+	require.Equal(t, "a`b", got) // doubled backquote collapse still applies
+}
+
+func TestCaseInsensitiveCollation(t *testing.T) {
+	require.True(t, CaseInsensitiveCollation.Equal('a', 'A'))
+	require.False(t, CaseInsensitiveCollation.Equal('a', 'b'))
+	require.Equal(t, CaseInsensitiveCollation.Weight('a'), CaseInsensitiveCollation.Weight('A'))
+
+	require.True(t, BinaryCollation.Equal('a', 'a'))
+	require.False(t, BinaryCollation.Equal('a', 'A'))
+}
+
+// TestCaseInsensitiveCollationScopeGap pins the accepted interim behavior
+// documented on the Collation/caseFoldCollation doc comments:
+// CaseInsensitiveCollation is a plain case fold, not a faithful
+// utf8mb4_unicode_ci/utf8mb4_0900_ai_ci implementation, so it does neither
+// accent folding nor multi-weight expansion. If this test starts failing
+// because those were implemented, update it (and the doc comments) rather
+// than treating it as a regression.
+func TestCaseInsensitiveCollationScopeGap(t *testing.T) {
+	// No accent folding: 'é' and 'e' are real utf8mb4_unicode_ci equals but
+	// are not equal under this stand-in.
+	require.False(t, CaseInsensitiveCollation.Equal('é', 'e'))
+
+	// No multi-weight expansion: a real utf8mb4_unicode_ci treats 'ß' as
+	// equivalent to "ss", so "straße" LIKE "strasse" would match; this
+	// stand-in compares 'ß' rune-for-rune against 's','s' and does not.
+	patWeights, patTypes := CompilePatternInner("strasse", '\\', CaseInsensitiveCollation)
+	require.False(t, DoMatchInner("straße", patWeights, patTypes, CaseInsensitiveCollation))
+}
+
+func TestPatternPrefixSuffixBinaryCollation(t *testing.T) {
+	p := Compile("abc%def", '\\', BinaryCollation)
+	require.True(t, p.Match("abcXYZdef"))
+	require.True(t, p.Match("abcdef"))
+	require.False(t, p.Match("abXdef"))
+	require.False(t, p.Match("abcde"))
+}
+
+func TestPatternExact(t *testing.T) {
+	p := Compile("abc", '\\', BinaryCollation)
+	require.True(t, p.Match("abc"))
+	require.False(t, p.Match("abcd"))
+	require.False(t, p.Match("ABC"))
+	require.True(t, p.MatchBytes([]byte("abc")))
+	require.False(t, p.MatchBytes([]byte("abcd")))
+}
+
+func TestPatternPrefix(t *testing.T) {
+	p := Compile("abc%", '\\', BinaryCollation)
+	require.True(t, p.Match("abc"))
+	require.True(t, p.Match("abcdef"))
+	require.False(t, p.Match("ab"))
+	require.True(t, p.MatchBytes([]byte("abcdef")))
+}
+
+func TestPatternSuffix(t *testing.T) {
+	p := Compile("%abc", '\\', BinaryCollation)
+	require.True(t, p.Match("abc"))
+	require.True(t, p.Match("xyzabc"))
+	require.False(t, p.Match("abcx"))
+	require.True(t, p.MatchBytes([]byte("xyzabc")))
+}
+
+func TestPatternContains(t *testing.T) {
+	p := Compile("%abc%", '\\', BinaryCollation)
+	require.True(t, p.Match("abc"))
+	require.True(t, p.Match("xxabcyy"))
+	require.False(t, p.Match("xyz"))
+	require.True(t, p.MatchBytes([]byte("xxabcyy")))
+}
+
+func TestPatternMatchNonBinaryCollation(t *testing.T) {
+	exact := Compile("abc", '\\', CaseInsensitiveCollation)
+	require.True(t, exact.Match("ABC"))
+	require.False(t, exact.Match("abd"))
+
+	prefix := Compile("abc%", '\\', CaseInsensitiveCollation)
+	require.True(t, prefix.Match("ABCdef"))
+
+	suffix := Compile("%abc", '\\', CaseInsensitiveCollation)
+	require.True(t, suffix.Match("xyzABC"))
+
+	contains := Compile("%abc%", '\\', CaseInsensitiveCollation)
+	require.True(t, contains.Match("xxABCyy"))
+
+	prefixSuffix := Compile("abc%def", '\\', CaseInsensitiveCollation)
+	require.True(t, prefixSuffix.Match("ABCxyzDEF"))
+	require.False(t, prefixSuffix.Match("ABdef"))
+
+	// MatchBytes on a non-binary collation falls back through Match,
+	// rather than the zero-copy binary-collation path.
+	require.True(t, exact.MatchBytes([]byte("ABC")))
+}
+
+func TestPatternGeneral(t *testing.T) {
+	p := Compile("a_c%", '\\', BinaryCollation)
+	require.True(t, p.Match("abc"))
+	require.True(t, p.Match("abcdef"))
+	require.False(t, p.Match("ac"))
+	require.True(t, p.MatchBytes([]byte("abcdef")))
+}
+
+func TestDoMatchInnerAdversarialRestart(t *testing.T) {
+	patWeights, patTypes := CompilePatternInner("a%a%a%a%b", '\\', BinaryCollation)
+	require.True(t, DoMatchInner("aaaaaaaaaaaaaaab", patWeights, patTypes, BinaryCollation))
+	require.False(t, DoMatchInner("aaaaaaaaaaaaaaa", patWeights, patTypes, BinaryCollation))
+}
+
+func TestDoMatchInnerCaseInsensitive(t *testing.T) {
+	patWeights, patTypes := CompilePatternInner("HE%O", '\\', CaseInsensitiveCollation)
+	require.True(t, DoMatchInner("hello", patWeights, patTypes, CaseInsensitiveCollation))
+	require.False(t, DoMatchInner("world", patWeights, patTypes, CaseInsensitiveCollation))
+}